@@ -0,0 +1,162 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pipelinex contains utilities for working with pipeline protos.
+package pipelinex
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	pb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// RenderJSON writes the pipeline as indented JSON. The output includes all
+// components, such as coders and windowing strategies.
+func RenderJSON(p *pb.Pipeline, w io.Writer) error {
+	return (&jsonpb.Marshaler{Indent: "  "}).Marshal(w, p)
+}
+
+// RenderDOT writes the pipeline as a Graphviz DOT graph. Composite transforms
+// are rendered as clusters of their subtransforms. PCollections are labelled
+// with their coder and windowing strategy. The output is deterministic.
+func RenderDOT(p *pb.Pipeline, w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	r := &renderer{c: p.GetComponents(), w: buf}
+
+	fmt.Fprintln(buf, "digraph G {")
+	for _, id := range p.GetRootTransformIds() {
+		r.transform(id, "  ")
+	}
+	for _, id := range sortedKeys(r.c.GetPcollections()) {
+		r.pcollection(id)
+	}
+	for _, e := range r.edges {
+		fmt.Fprintf(buf, "  %v -> %v;\n", quote(e[0]), quote(e[1]))
+	}
+	fmt.Fprintln(buf, "}")
+	return buf.Flush()
+}
+
+type renderer struct {
+	c     *pb.Components
+	w     io.Writer
+	edges [][2]string
+}
+
+func (r *renderer) transform(id, indent string) {
+	t := r.c.GetTransforms()[id]
+	if t == nil {
+		return // dangling reference: ignore
+	}
+
+	if len(t.GetSubtransforms()) > 0 {
+		fmt.Fprintf(r.w, "%vsubgraph %v {\n", indent, quote("cluster_"+id))
+		fmt.Fprintf(r.w, "%v  label=%v;\n", indent, quote(t.GetUniqueName()))
+		for _, sub := range t.GetSubtransforms() {
+			r.transform(sub, indent+"  ")
+		}
+		fmt.Fprintf(r.w, "%v}\n", indent)
+		return
+	}
+
+	label := t.GetUniqueName()
+	if urn := t.GetSpec().GetUrn(); urn != "" {
+		label += "\n" + urn
+	}
+	fmt.Fprintf(r.w, "%v%v [shape=box, label=%v];\n", indent, quote(transformNode(id)), quote(label))
+
+	// Composite inputs and outputs are repeated by their leaves, so we
+	// only add edges for primitive transforms.
+	for _, in := range sortedValues(t.GetInputs()) {
+		r.edges = append(r.edges, [2]string{pcollectionNode(in), transformNode(id)})
+	}
+	for _, out := range sortedValues(t.GetOutputs()) {
+		r.edges = append(r.edges, [2]string{transformNode(id), pcollectionNode(out)})
+	}
+}
+
+func (r *renderer) pcollection(id string) {
+	c := r.c.GetPcollections()[id]
+
+	label := fmt.Sprintf("%v\ncoder: %v", c.GetUniqueName(), r.coder(c.GetCoderId()))
+	if ws, ok := r.c.GetWindowingStrategies()[c.GetWindowingStrategyId()]; ok {
+		label += fmt.Sprintf("\nwindowing: %v", ws.GetWindowFn().GetSpec().GetUrn())
+	}
+	fmt.Fprintf(r.w, "  %v [shape=ellipse, label=%v];\n", quote(pcollectionNode(id)), quote(label))
+}
+
+// coder returns a readable name for the coder, such as "urn:kv(urn:a, urn:b)".
+func (r *renderer) coder(id string) string {
+	c, ok := r.c.GetCoders()[id]
+	if !ok {
+		return id
+	}
+	name := c.GetSpec().GetSpec().GetUrn()
+	if name == "" {
+		name = id
+	}
+	if len(c.GetComponentCoderIds()) == 0 {
+		return name
+	}
+
+	var components []string
+	for _, sub := range c.GetComponentCoderIds() {
+		components = append(components, r.coder(sub))
+	}
+	return fmt.Sprintf("%v(%v)", name, strings.Join(components, ", "))
+}
+
+// transformNode returns the DOT node ID of the transform. Transform and
+// PCollection IDs are scoped separately in the pipeline proto, so node IDs
+// are prefixed to keep them apart.
+func transformNode(id string) string {
+	return "t:" + id
+}
+
+// pcollectionNode returns the DOT node ID of the PCollection.
+func pcollectionNode(id string) string {
+	return "p:" + id
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quote returns the string as a DOT quoted string. Newlines are rendered as
+// DOT line breaks.
+func quote(s string) string {
+	return `"` + escaper.Replace(s) + `"`
+}
+
+func sortedKeys(m map[string]*pb.PCollection) []string {
+	var ret []string
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func sortedValues(m map[string]string) []string {
+	var ret []string
+	for _, v := range m {
+		ret = append(ret, v)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,153 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinex
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	pb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+func makePipeline() *pb.Pipeline {
+	spec := func(urn string) *pb.SdkFunctionSpec {
+		return &pb.SdkFunctionSpec{Spec: &pb.FunctionSpec{Urn: urn}}
+	}
+
+	return &pb.Pipeline{
+		Components: &pb.Components{
+			Transforms: map[string]*pb.PTransform{
+				"c": {
+					UniqueName:    "Composite",
+					Subtransforms: []string{"t1", "t2"},
+					Outputs:       map[string]string{"out": "p2"},
+				},
+				"t1": {
+					UniqueName: "Composite/Impulse",
+					Spec:       &pb.FunctionSpec{Urn: "urn:impulse"},
+					Outputs:    map[string]string{"out": "p1"},
+				},
+				"t2": {
+					UniqueName: "Composite/ParDo",
+					Spec:       &pb.FunctionSpec{Urn: "urn:pardo"},
+					Inputs:     map[string]string{"in": "p1"},
+					Outputs:    map[string]string{"out": "p2"},
+				},
+			},
+			Pcollections: map[string]*pb.PCollection{
+				"p1": {UniqueName: "p1", CoderId: "bytes", WindowingStrategyId: "global"},
+				"p2": {UniqueName: "p2", CoderId: "kv", WindowingStrategyId: "global"},
+			},
+			Coders: map[string]*pb.Coder{
+				"bytes": {Spec: spec("urn:bytes")},
+				"kv":    {Spec: spec("urn:kv"), ComponentCoderIds: []string{"bytes", "bytes"}},
+			},
+			WindowingStrategies: map[string]*pb.WindowingStrategy{
+				"global": {WindowFn: spec("urn:global")},
+			},
+		},
+		RootTransformIds: []string{"c"},
+	}
+}
+
+// TestRenderDOT verifies that the DOT output contains clusters, labelled
+// nodes and edges in a deterministic order.
+func TestRenderDOT(t *testing.T) {
+	const want = `digraph G {
+  subgraph "cluster_c" {
+    label="Composite";
+    "t:t1" [shape=box, label="Composite/Impulse\nurn:impulse"];
+    "t:t2" [shape=box, label="Composite/ParDo\nurn:pardo"];
+  }
+  "p:p1" [shape=ellipse, label="p1\ncoder: urn:bytes\nwindowing: urn:global"];
+  "p:p2" [shape=ellipse, label="p2\ncoder: urn:kv(urn:bytes, urn:bytes)\nwindowing: urn:global"];
+  "t:t1" -> "p:p1";
+  "p:p1" -> "t:t2";
+  "t:t2" -> "p:p2";
+}
+`
+
+	var buf bytes.Buffer
+	if err := RenderDOT(makePipeline(), &buf); err != nil {
+		t.Fatalf("RenderDOT failed: %v", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("RenderDOT() = %v, want %v", got, want)
+	}
+}
+
+// TestRenderDOTCollision verifies that a transform and a PCollection with
+// the same ID are rendered as distinct nodes.
+func TestRenderDOTCollision(t *testing.T) {
+	p := &pb.Pipeline{
+		Components: &pb.Components{
+			Transforms: map[string]*pb.PTransform{
+				"x": {UniqueName: "Impulse", Outputs: map[string]string{"out": "x"}},
+			},
+			Pcollections: map[string]*pb.PCollection{
+				"x": {UniqueName: "out"},
+			},
+		},
+		RootTransformIds: []string{"x"},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderDOT(p, &buf); err != nil {
+		t.Fatalf("RenderDOT failed: %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, `"t:x" -> "p:x";`) {
+		t.Errorf("RenderDOT() = %v, want edge from t:x to p:x", got)
+	}
+}
+
+// TestQuote verifies that only DOT escapes are used.
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"foo", `"foo"`},
+		{`a "b"`, `"a \"b\""`},
+		{`a\b`, `"a\\b"`},
+		{"a\nb", `"a\nb"`},
+		{"ä\tb", "\"ä\tb\""},
+	}
+
+	for _, test := range tests {
+		if got := quote(test.in); got != test.want {
+			t.Errorf("quote(%q) = %v, want %v", test.in, got, test.want)
+		}
+	}
+}
+
+// TestRenderJSON verifies that the JSON output decodes to the pipeline.
+func TestRenderJSON(t *testing.T) {
+	p := makePipeline()
+
+	var buf bytes.Buffer
+	if err := RenderJSON(p, &buf); err != nil {
+		t.Fatalf("RenderJSON failed: %v", err)
+	}
+	var ret pb.Pipeline
+	if err := jsonpb.Unmarshal(&buf, &ret); err != nil {
+		t.Fatalf("failed to unmarshal JSON: %v", err)
+	}
+	if !proto.Equal(p, &ret) {
+		t.Errorf("Unmarshal(RenderJSON(%v)) = %v, want %v", p, &ret, p)
+	}
+}
@@ -0,0 +1,178 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinex
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	pb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
+)
+
+// CheckUpdate verifies that the next pipeline can update the running prev
+// pipeline in place. Every primitive transform in prev must have a transform
+// with the same unique name in next. It must have the same URN, consume the
+// same outputs of the same upstream transforms, and its outputs must be
+// encoded with the same coders. The mapping renames transforms from prev
+// to next. A key matches a transform name exactly or as a prefix ending
+// at a "/", so renaming a composite also renames its subtransforms. An empty
+// new name means that the transforms are deliberately removed. It returns
+// an error listing all incompatibilities, if any.
+func CheckUpdate(prev, next *pb.Pipeline, mapping map[string]string) error {
+	var errs []string
+
+	names := make(map[string]*pb.PTransform)
+	for _, t := range next.GetComponents().GetTransforms() {
+		if _, ok := names[t.GetUniqueName()]; ok {
+			errs = append(errs, fmt.Sprintf("transform name %q is not unique", t.GetUniqueName()))
+		}
+		names[t.GetUniqueName()] = t
+	}
+	prevProducers := producers(prev)
+	nextProducers := producers(next)
+
+	used := make(map[string]bool)
+	for _, t := range prev.GetComponents().GetTransforms() {
+		name, key, ok := rename(t.GetUniqueName(), mapping)
+		if key != "" {
+			used[key] = true
+		}
+		if !ok || len(t.GetSubtransforms()) > 0 {
+			continue // removed or composite: checked via its leaves
+		}
+
+		repl, ok := names[name]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("transform %q not found in new pipeline", t.GetUniqueName()))
+			continue
+		}
+		if a, b := t.GetSpec().GetUrn(), repl.GetSpec().GetUrn(); a != b {
+			errs = append(errs, fmt.Sprintf("transform %q changed URN from %q to %q", t.GetUniqueName(), a, b))
+		}
+
+		for local, id := range t.GetInputs() {
+			in, ok := repl.GetInputs()[local]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("transform %q: input %q not found in new pipeline", t.GetUniqueName(), local))
+				continue
+			}
+			want := prevProducers[id]
+			want.name, _, _ = rename(want.name, mapping)
+			if got := nextProducers[in]; got != want {
+				errs = append(errs, fmt.Sprintf("transform %q: input %q rewired from %v to %v", t.GetUniqueName(), local, want, got))
+			}
+		}
+
+		for local, id := range t.GetOutputs() {
+			out, ok := repl.GetOutputs()[local]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("transform %q: output %q not found in new pipeline", t.GetUniqueName(), local))
+				continue
+			}
+			a := prev.GetComponents().GetPcollections()[id].GetCoderId()
+			b := next.GetComponents().GetPcollections()[out].GetCoderId()
+			if !equalCoders(prev.GetComponents(), a, next.GetComponents(), b) {
+				errs = append(errs, fmt.Sprintf("transform %q: output %q changed coder", t.GetUniqueName(), local))
+			}
+		}
+	}
+
+	for name := range mapping {
+		if !used[name] {
+			errs = append(errs, fmt.Sprintf("mapped transform %q not found in running pipeline", name))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return errors.New("incompatible pipeline update:\n\t" + strings.Join(errs, "\n\t"))
+}
+
+// rename applies the longest matching mapping key to the transform name.
+// It returns the new name, the matching key, if any, and false if the
+// transform is removed.
+func rename(name string, mapping map[string]string) (string, string, bool) {
+	var key string
+	for k := range mapping {
+		if (name == k || strings.HasPrefix(name, k+"/")) && len(k) > len(key) {
+			key = k
+		}
+	}
+	if key == "" {
+		return name, "", true
+	}
+	if mapping[key] == "" {
+		return "", key, false
+	}
+	return mapping[key] + name[len(key):], key, true
+}
+
+// output identifies a PCollection by its producing transform.
+type output struct {
+	name, local string
+}
+
+func (o output) String() string {
+	return fmt.Sprintf("%q output %q", o.name, o.local)
+}
+
+// producers returns the primitive producer of each PCollection.
+func producers(p *pb.Pipeline) map[string]output {
+	ret := make(map[string]output)
+	for _, t := range p.GetComponents().GetTransforms() {
+		if len(t.GetSubtransforms()) > 0 {
+			continue // composite: outputs are produced by its leaves
+		}
+		for local, id := range t.GetOutputs() {
+			ret[id] = output{name: t.GetUniqueName(), local: local}
+		}
+	}
+	return ret
+}
+
+// equalCoders compares coders structurally, because coder IDs are not
+// stable across pipelines.
+func equalCoders(ac *pb.Components, a string, bc *pb.Components, b string) bool {
+	x, ok := ac.GetCoders()[a]
+	if !ok {
+		return false
+	}
+	y, ok := bc.GetCoders()[b]
+	if !ok {
+		return false
+	}
+
+	if x.GetSpec().GetSpec().GetUrn() != y.GetSpec().GetSpec().GetUrn() {
+		return false
+	}
+	if !bytes.Equal(x.GetSpec().GetSpec().GetPayload(), y.GetSpec().GetSpec().GetPayload()) {
+		return false
+	}
+	if len(x.GetComponentCoderIds()) != len(y.GetComponentCoderIds()) {
+		return false
+	}
+	for i, sub := range x.GetComponentCoderIds() {
+		if !equalCoders(ac, sub, bc, y.GetComponentCoderIds()[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinex
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
+)
+
+// TestCheckUpdate verifies that renames, removals, URN, input and coder
+// changes are detected and that name mappings are honored.
+func TestCheckUpdate(t *testing.T) {
+	renameLeaf := func(p *pb.Pipeline) {
+		p.GetComponents().GetTransforms()["t2"].UniqueName = "Composite/Map"
+	}
+	renameComposite := func(p *pb.Pipeline) {
+		for _, t := range p.GetComponents().GetTransforms() {
+			t.UniqueName = strings.Replace(t.UniqueName, "Composite", "Renamed", 1)
+		}
+	}
+	remove := func(p *pb.Pipeline) {
+		delete(p.GetComponents().GetTransforms(), "t2")
+	}
+	recode := func(p *pb.Pipeline) {
+		p.GetComponents().GetPcollections()["p2"].CoderId = "bytes"
+	}
+	respec := func(p *pb.Pipeline) {
+		p.GetComponents().GetTransforms()["t2"].Spec = &pb.FunctionSpec{Urn: "urn:gbk"}
+	}
+	duplicate := func(p *pb.Pipeline) {
+		p.GetComponents().GetTransforms()["t3"] = &pb.PTransform{
+			UniqueName: "Composite/ParDo",
+			Spec:       &pb.FunctionSpec{Urn: "urn:pardo"},
+		}
+	}
+	rewire := func(p *pb.Pipeline) {
+		p.GetComponents().GetTransforms()["t3"] = &pb.PTransform{
+			UniqueName: "Composite/Other",
+			Spec:       &pb.FunctionSpec{Urn: "urn:impulse"},
+			Outputs:    map[string]string{"out": "p3"},
+		}
+		p.GetComponents().GetPcollections()["p3"] = &pb.PCollection{UniqueName: "p3", CoderId: "bytes"}
+		p.GetComponents().GetTransforms()["t2"].Inputs = map[string]string{"in": "p3"}
+	}
+
+	tests := []struct {
+		change  func(p *pb.Pipeline)
+		mapping map[string]string
+		err     string // expected error substring, if any
+	}{
+		{nil, nil, ""},
+		{renameLeaf, nil, `transform "Composite/ParDo" not found in new pipeline`},
+		{renameLeaf, map[string]string{"Composite/ParDo": "Composite/Map"}, ""},
+		{renameComposite, nil, `transform "Composite/Impulse" not found in new pipeline`},
+		{renameComposite, map[string]string{"Composite": "Renamed"}, ""},
+		{remove, nil, `transform "Composite/ParDo" not found in new pipeline`},
+		{remove, map[string]string{"Composite/ParDo": ""}, ""},
+		{recode, nil, `transform "Composite/ParDo": output "out" changed coder`},
+		{respec, nil, `transform "Composite/ParDo" changed URN from "urn:pardo" to "urn:gbk"`},
+		{duplicate, nil, `transform name "Composite/ParDo" is not unique`},
+		{rewire, nil, `transform "Composite/ParDo": input "in" rewired`},
+		{nil, map[string]string{"Missing": "Other"}, `mapped transform "Missing" not found in running pipeline`},
+		{nil, map[string]string{"Comp": "Other"}, `mapped transform "Comp" not found in running pipeline`},
+	}
+
+	for i, test := range tests {
+		next := makePipeline()
+		if test.change != nil {
+			test.change(next)
+		}
+
+		err := CheckUpdate(makePipeline(), next, test.mapping)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("CheckUpdate(%v) failed: %v", i, err)
+		case test.err != "" && err == nil:
+			t.Errorf("CheckUpdate(%v) succeeded, want error %q", i, test.err)
+		case test.err != "" && !strings.Contains(err.Error(), test.err):
+			t.Errorf("CheckUpdate(%v) = %v, want error %q", i, err, test.err)
+		}
+	}
+}
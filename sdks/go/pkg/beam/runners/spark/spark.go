@@ -0,0 +1,62 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spark contains a job submission helper for the portable Spark
+// runner.
+package spark
+
+import (
+	"context"
+
+	pipepb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
+	"github.com/apache/beam/sdks/go/pkg/beam/runners/universal"
+)
+
+// Options contains the Spark job submission options.
+type Options struct {
+	universal.Options
+
+	// Master is the (optional) Spark master URL, such as spark://host:7077.
+	// If not set, the job service decides.
+	Master string
+}
+
+// Execute submits the pipeline to the Spark job service. It returns the
+// handle to the running job, if successful. The caller must close the handle.
+func Execute(ctx context.Context, p *pipepb.Pipeline, opt *Options) (*universal.Job, error) {
+	o := opt.Options
+	o.PipelineOptions = options(opt)
+	return universal.Execute(ctx, p, &o)
+}
+
+// runner is the fully qualified runner class name. The job service decodes
+// options with Jackson, which does not resolve short runner names.
+const runner = "org.apache.beam.runners.spark.SparkRunner"
+
+// options returns a copy of the pipeline options with the runner options
+// added. A runner set by the caller is kept.
+func options(opt *Options) map[string]interface{} {
+	ret := make(map[string]interface{})
+	for k, v := range opt.PipelineOptions {
+		ret[k] = v
+	}
+	if _, ok := ret["runner"]; !ok {
+		ret["runner"] = runner
+	}
+	if opt.Master != "" {
+		ret["sparkMaster"] = opt.Master
+	}
+	return ret
+}
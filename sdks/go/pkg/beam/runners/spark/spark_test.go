@@ -0,0 +1,69 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spark
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/runners/universal"
+)
+
+// TestOptions verifies that the Spark options are added to a copy of the
+// pipeline options.
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		opt  Options
+		want map[string]interface{}
+	}{
+		{
+			Options{},
+			map[string]interface{}{"runner": runner},
+		},
+		{
+			Options{Master: "spark://host:7077"},
+			map[string]interface{}{"runner": runner, "sparkMaster": "spark://host:7077"},
+		},
+		{
+			Options{
+				Options: universal.Options{PipelineOptions: map[string]interface{}{"a": "b"}},
+				Master:  "spark://host:7077",
+			},
+			map[string]interface{}{"a": "b", "runner": runner, "sparkMaster": "spark://host:7077"},
+		},
+		{
+			Options{
+				Options: universal.Options{PipelineOptions: map[string]interface{}{"runner": "org.example.MyRunner"}},
+				Master:  "spark://host:7077",
+			},
+			map[string]interface{}{"runner": "org.example.MyRunner", "sparkMaster": "spark://host:7077"},
+		},
+	}
+
+	for _, test := range tests {
+		orig := make(map[string]interface{})
+		for k, v := range test.opt.PipelineOptions {
+			orig[k] = v
+		}
+
+		if got := options(&test.opt); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("options(%+v) = %v, want %v", test.opt, got, test.want)
+		}
+		if len(orig) > 0 && !reflect.DeepEqual(test.opt.PipelineOptions, orig) {
+			t.Errorf("options(%+v) modified pipeline options, want %v", test.opt, orig)
+		}
+	}
+}
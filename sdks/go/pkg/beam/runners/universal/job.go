@@ -0,0 +1,117 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package universal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	jobpb "github.com/apache/beam/sdks/go/pkg/beam/model/jobmanagement_v1"
+	"github.com/apache/beam/sdks/go/pkg/beam/util/grpcx"
+)
+
+// Job is a handle to a job on a job service. The Beam Job API has no drain
+// request, so jobs can be cancelled but not drained.
+type Job struct {
+	// ID is the job ID assigned by the job service.
+	ID string
+
+	client jobpb.JobServiceClient
+	cc     io.Closer
+}
+
+// Attach returns a handle to an existing job on the job service at the
+// given endpoint. The caller must close the handle.
+func Attach(ctx context.Context, endpoint, id string) (*Job, error) {
+	cc, err := grpcx.Dial(ctx, endpoint, 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	return &Job{ID: id, client: jobpb.NewJobServiceClient(cc), cc: cc}, nil
+}
+
+// State returns the current state of the job.
+func (j *Job) State(ctx context.Context) (jobpb.JobState_Enum, error) {
+	resp, err := j.client.GetState(ctx, &jobpb.GetJobStateRequest{JobId: j.ID})
+	if err != nil {
+		return jobpb.JobState_UNSPECIFIED, fmt.Errorf("failed to get state of job %v: %v", j.ID, err)
+	}
+	return resp.GetState(), nil
+}
+
+// Cancel requests that the job be cancelled. It returns the resulting state,
+// which is either CANCELLING or a terminal state.
+func (j *Job) Cancel(ctx context.Context) (jobpb.JobState_Enum, error) {
+	resp, err := j.client.Cancel(ctx, &jobpb.CancelJobRequest{JobId: j.ID})
+	if err != nil {
+		return jobpb.JobState_UNSPECIFIED, fmt.Errorf("failed to cancel job %v: %v", j.ID, err)
+	}
+	return resp.GetState(), nil
+}
+
+// WaitUntilFinish waits for the job to reach a terminal state and returns
+// it. If the timeout is positive and expires first, it returns the last
+// observed state and an error.
+func (j *Job) WaitUntilFinish(ctx context.Context, timeout time.Duration) (jobpb.JobState_Enum, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	stream, err := j.client.GetStateStream(ctx, &jobpb.GetJobStateRequest{JobId: j.ID})
+	if err != nil {
+		return jobpb.JobState_UNSPECIFIED, fmt.Errorf("failed to get state stream of job %v: %v", j.ID, err)
+	}
+
+	state := jobpb.JobState_UNSPECIFIED
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				err = errors.New("state stream closed")
+			}
+			return state, fmt.Errorf("job %v not finished in state %v: %v", j.ID, state, err)
+		}
+
+		state = resp.GetState()
+		if IsTerminal(state) {
+			return state, nil
+		}
+	}
+}
+
+// Close closes the connection to the job service. It does not affect the
+// job itself.
+func (j *Job) Close() error {
+	if j.cc == nil {
+		return nil
+	}
+	return j.cc.Close()
+}
+
+// IsTerminal returns true iff the job state is final.
+func IsTerminal(state jobpb.JobState_Enum) bool {
+	switch state {
+	case jobpb.JobState_DONE, jobpb.JobState_FAILED, jobpb.JobState_CANCELLED, jobpb.JobState_UPDATED, jobpb.JobState_DRAINED:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,79 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package universal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	jobpb "github.com/apache/beam/sdks/go/pkg/beam/model/jobmanagement_v1"
+)
+
+// TestState verifies that the job state and cancellation are forwarded to
+// the job service.
+func TestState(t *testing.T) {
+	c := &client{states: []jobpb.JobState_Enum{jobpb.JobState_RUNNING}}
+	j := &Job{ID: "job", client: c}
+	ctx := context.Background()
+
+	state, err := j.State(ctx)
+	if err != nil || state != jobpb.JobState_RUNNING {
+		t.Errorf("State() = (%v, %v), want RUNNING", state, err)
+	}
+	state, err = j.Cancel(ctx)
+	if err != nil || state != jobpb.JobState_CANCELLING {
+		t.Errorf("Cancel() = (%v, %v), want CANCELLING", state, err)
+	}
+	if id := c.cancel.GetJobId(); id != "job" {
+		t.Errorf("cancelled job = %v, want job", id)
+	}
+}
+
+// TestWaitUntilFinish verifies that waiting returns the first terminal state
+// and fails if the state stream ends before that.
+func TestWaitUntilFinish(t *testing.T) {
+	tests := []struct {
+		states []jobpb.JobState_Enum
+		want   jobpb.JobState_Enum
+		ok     bool
+	}{
+		{[]jobpb.JobState_Enum{jobpb.JobState_STARTING, jobpb.JobState_RUNNING, jobpb.JobState_DONE}, jobpb.JobState_DONE, true},
+		{[]jobpb.JobState_Enum{jobpb.JobState_RUNNING, jobpb.JobState_FAILED, jobpb.JobState_DONE}, jobpb.JobState_FAILED, true},
+		{[]jobpb.JobState_Enum{jobpb.JobState_RUNNING, jobpb.JobState_CANCELLING}, jobpb.JobState_CANCELLING, false},
+	}
+
+	for _, test := range tests {
+		j := &Job{ID: "job", client: &client{states: test.states}}
+
+		state, err := j.WaitUntilFinish(context.Background(), 0)
+		if state != test.want || (err == nil) != test.ok {
+			t.Errorf("WaitUntilFinish(%v) = (%v, %v), want %v", test.states, state, err, test.want)
+		}
+	}
+}
+
+// TestWaitUntilFinishTimeout verifies that the last observed state is
+// returned with an error if the timeout expires.
+func TestWaitUntilFinishTimeout(t *testing.T) {
+	c := &client{states: []jobpb.JobState_Enum{jobpb.JobState_RUNNING}, block: true}
+	j := &Job{ID: "job", client: c}
+
+	state, err := j.WaitUntilFinish(context.Background(), 10*time.Millisecond)
+	if state != jobpb.JobState_RUNNING || err == nil {
+		t.Errorf("WaitUntilFinish() = (%v, %v), want RUNNING and error", state, err)
+	}
+}
@@ -0,0 +1,161 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package universal contains utilities for submitting pipelines to a
+// portable runner via the Beam Job API.
+package universal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/artifact"
+	jobpb "github.com/apache/beam/sdks/go/pkg/beam/model/jobmanagement_v1"
+	pipepb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
+	"github.com/apache/beam/sdks/go/pkg/beam/provision"
+	"github.com/apache/beam/sdks/go/pkg/beam/util/grpcx"
+	"github.com/golang/protobuf/proto"
+)
+
+// Options contains the job submission options.
+type Options struct {
+	// Endpoint is the job service endpoint, such as localhost:8099.
+	Endpoint string
+	// JobName is the (optional) name of the job.
+	JobName string
+	// Environment is the (optional) container image URL. If set, it
+	// overrides the URL of every environment in the pipeline.
+	Environment string
+	// Artifacts are the (optional) local files to stage for the job.
+	Artifacts []artifact.KeyedFile
+	// PipelineOptions are the (optional) pipeline options passed to the
+	// runner. Keys must be the runner option names. They are sent wrapped
+	// as {"options": {...}}.
+	PipelineOptions map[string]interface{}
+}
+
+// Execute prepares the pipeline, stages any artifacts and runs the job on
+// the job service. It returns a handle to the running job, if successful.
+// The caller must close the handle.
+func Execute(ctx context.Context, p *pipepb.Pipeline, opt *Options) (*Job, error) {
+	if opt.Endpoint == "" {
+		return nil, errors.New("job service endpoint not defined")
+	}
+
+	cc, err := grpcx.Dial(ctx, opt.Endpoint, 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	return execute(ctx, jobpb.NewJobServiceClient(cc), cc, p, opt)
+}
+
+// execute runs the job using the given client. The connection is closed on
+// failure and otherwise owned by the returned job.
+func execute(ctx context.Context, client jobpb.JobServiceClient, cc io.Closer, p *pipepb.Pipeline, opt *Options) (*Job, error) {
+	id, endpoint, err := Prepare(ctx, client, p, opt)
+	if err != nil {
+		cc.Close()
+		return nil, err
+	}
+	token, err := Stage(ctx, id, endpoint, opt.Artifacts)
+	if err != nil {
+		cc.Close()
+		return nil, err
+	}
+	jobID, err := Submit(ctx, client, id, token)
+	if err != nil {
+		cc.Close()
+		return nil, err
+	}
+	return &Job{ID: jobID, client: client, cc: cc}, nil
+}
+
+// Prepare prepares a job for the given pipeline. It returns the preparation
+// ID and the artifact staging endpoint.
+func Prepare(ctx context.Context, client jobpb.JobServiceClient, p *pipepb.Pipeline, opt *Options) (id, endpoint string, err error) {
+	if opt.Environment != "" {
+		p = proto.Clone(p).(*pipepb.Pipeline)
+		for _, env := range p.GetComponents().GetEnvironments() {
+			env.Url = opt.Environment
+		}
+	}
+	raw := opt.PipelineOptions
+	if raw == nil {
+		raw = make(map[string]interface{}) // encode as {}, not null
+	}
+	// The Java job service expects options in the same wrapped form that
+	// PipelineOptionsTranslation.toProto produces.
+	options, err := provision.OptionsToProto(map[string]interface{}{"options": raw})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to convert pipeline options: %v", err)
+	}
+
+	req := &jobpb.PrepareJobRequest{
+		Pipeline:        p,
+		PipelineOptions: options,
+		JobName:         opt.JobName,
+	}
+	resp, err := client.Prepare(ctx, req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to prepare job: %v", err)
+	}
+	return resp.GetPreparationId(), resp.GetArtifactStagingEndpoint().GetUrl(), nil
+}
+
+// Stage stages the given files to the artifact staging endpoint for the
+// prepared job. It returns the staging token, which is empty if there are
+// no files to stage.
+func Stage(ctx context.Context, id, endpoint string, files []artifact.KeyedFile) (string, error) {
+	if len(files) == 0 {
+		return "", nil
+	}
+	if endpoint == "" {
+		return "", fmt.Errorf("no artifact staging endpoint for job %v", id)
+	}
+
+	ctx = grpcx.WriteWorkerID(ctx, id)
+	cc, err := grpcx.Dial(ctx, endpoint, 2*time.Minute)
+	if err != nil {
+		return "", err
+	}
+	defer cc.Close()
+
+	client := jobpb.NewArtifactStagingServiceClient(cc)
+	md, err := artifact.MultiStage(ctx, client, 10, files)
+	if err != nil {
+		return "", fmt.Errorf("failed to stage artifacts: %v", err)
+	}
+	token, err := artifact.Commit(ctx, client, md)
+	if err != nil {
+		return "", fmt.Errorf("failed to commit artifacts: %v", err)
+	}
+	return token, nil
+}
+
+// Submit runs the prepared job. It returns the job ID.
+func Submit(ctx context.Context, client jobpb.JobServiceClient, id, token string) (string, error) {
+	req := &jobpb.RunJobRequest{
+		PreparationId: id,
+		StagingToken:  token,
+	}
+	resp, err := client.Run(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to submit job: %v", err)
+	}
+	return resp.GetJobId(), nil
+}
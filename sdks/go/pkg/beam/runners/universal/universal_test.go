@@ -0,0 +1,230 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package universal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/artifact"
+	jobpb "github.com/apache/beam/sdks/go/pkg/beam/model/jobmanagement_v1"
+	pipepb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
+	"github.com/apache/beam/sdks/go/pkg/beam/provision"
+	"google.golang.org/grpc"
+)
+
+// client is a fake job service client that records the requests. The job
+// goes through the given states.
+type client struct {
+	prepare *jobpb.PrepareJobRequest
+	run     *jobpb.RunJobRequest
+	cancel  *jobpb.CancelJobRequest
+
+	endpoint           string
+	prepareErr, runErr error
+	states             []jobpb.JobState_Enum
+	block              bool // block state stream after states until done
+}
+
+func (c *client) Prepare(ctx context.Context, in *jobpb.PrepareJobRequest, opts ...grpc.CallOption) (*jobpb.PrepareJobResponse, error) {
+	c.prepare = in
+	if c.prepareErr != nil {
+		return nil, c.prepareErr
+	}
+	return &jobpb.PrepareJobResponse{
+		PreparationId:           "prep",
+		ArtifactStagingEndpoint: &pipepb.ApiServiceDescriptor{Url: c.endpoint},
+	}, nil
+}
+
+func (c *client) Run(ctx context.Context, in *jobpb.RunJobRequest, opts ...grpc.CallOption) (*jobpb.RunJobResponse, error) {
+	c.run = in
+	if c.runErr != nil {
+		return nil, c.runErr
+	}
+	return &jobpb.RunJobResponse{JobId: "job"}, nil
+}
+
+func (c *client) GetState(ctx context.Context, in *jobpb.GetJobStateRequest, opts ...grpc.CallOption) (*jobpb.GetJobStateResponse, error) {
+	return &jobpb.GetJobStateResponse{State: c.states[0]}, nil
+}
+
+func (c *client) Cancel(ctx context.Context, in *jobpb.CancelJobRequest, opts ...grpc.CallOption) (*jobpb.CancelJobResponse, error) {
+	c.cancel = in
+	return &jobpb.CancelJobResponse{State: jobpb.JobState_CANCELLING}, nil
+}
+
+func (c *client) GetStateStream(ctx context.Context, in *jobpb.GetJobStateRequest, opts ...grpc.CallOption) (jobpb.JobService_GetStateStreamClient, error) {
+	return &stream{ctx: ctx, states: c.states, block: c.block}, nil
+}
+
+func (c *client) GetMessageStream(ctx context.Context, in *jobpb.JobMessagesRequest, opts ...grpc.CallOption) (jobpb.JobService_GetMessageStreamClient, error) {
+	panic("not implemented")
+}
+
+// stream is a fake state stream that returns the given states. It then
+// either ends or blocks until the context is done.
+type stream struct {
+	grpc.ClientStream
+	ctx    context.Context
+	states []jobpb.JobState_Enum
+	block  bool
+}
+
+func (s *stream) Recv() (*jobpb.GetJobStateResponse, error) {
+	if len(s.states) == 0 {
+		if s.block {
+			<-s.ctx.Done()
+			return nil, s.ctx.Err()
+		}
+		return nil, io.EOF
+	}
+	state := s.states[0]
+	s.states = s.states[1:]
+	return &jobpb.GetJobStateResponse{State: state}, nil
+}
+
+// closer is a fake connection that records whether it was closed.
+type closer struct {
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return nil
+}
+
+// TestPrepare verifies that preparation applies the environment and
+// pipeline options without modifying the given pipeline.
+func TestPrepare(t *testing.T) {
+	p := &pipepb.Pipeline{
+		Components: &pipepb.Components{
+			Environments: map[string]*pipepb.Environment{
+				"go": {Url: "old"},
+			},
+		},
+	}
+	opt := &Options{
+		JobName:         "foo",
+		Environment:     "new",
+		PipelineOptions: map[string]interface{}{"a": "b"},
+	}
+
+	c := &client{endpoint: "localhost:1234"}
+	id, endpoint, err := Prepare(context.Background(), c, p, opt)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if id != "prep" || endpoint != "localhost:1234" {
+		t.Errorf("Prepare() = (%v, %v), want (prep, localhost:1234)", id, endpoint)
+	}
+
+	if name := c.prepare.GetJobName(); name != "foo" {
+		t.Errorf("job name = %v, want foo", name)
+	}
+	if url := c.prepare.GetPipeline().GetComponents().GetEnvironments()["go"].GetUrl(); url != "new" {
+		t.Errorf("environment = %v, want new", url)
+	}
+	if url := p.GetComponents().GetEnvironments()["go"].GetUrl(); url != "old" {
+		t.Errorf("original environment = %v, want old", url)
+	}
+
+	var options map[string]interface{}
+	if err := provision.ProtoToOptions(c.prepare.GetPipelineOptions(), &options); err != nil {
+		t.Fatalf("failed to decode options: %v", err)
+	}
+	want := map[string]interface{}{
+		"options": map[string]interface{}{"a": "b"},
+	}
+	if !reflect.DeepEqual(options, want) {
+		t.Errorf("options = %v, want %v", options, want)
+	}
+}
+
+// TestSubmit verifies that submission runs the prepared job with the
+// staging token.
+func TestSubmit(t *testing.T) {
+	c := &client{}
+	id, err := Submit(context.Background(), c, "prep", "token")
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if id != "job" {
+		t.Errorf("Submit() = %v, want job", id)
+	}
+	if c.run.GetPreparationId() != "prep" || c.run.GetStagingToken() != "token" {
+		t.Errorf("run request = %v, want prep and token", c.run)
+	}
+}
+
+// TestStage verifies that staging is skipped without files and fails
+// without a staging endpoint.
+func TestStage(t *testing.T) {
+	ctx := context.Background()
+
+	token, err := Stage(ctx, "prep", "", nil)
+	if token != "" || err != nil {
+		t.Errorf("Stage(nil) = (%v, %v), want no token", token, err)
+	}
+	files := []artifact.KeyedFile{{Key: "a", Filename: "a"}}
+	if _, err := Stage(ctx, "prep", "", files); err == nil {
+		t.Errorf("Stage(%v) succeeded without endpoint, want error", files)
+	}
+}
+
+// TestExecute verifies that the connection is closed if any step fails and
+// is owned by the job otherwise.
+func TestExecute(t *testing.T) {
+	boom := errors.New("boom")
+	files := []artifact.KeyedFile{{Key: "a", Filename: "a"}}
+
+	tests := []struct {
+		c     *client
+		files []artifact.KeyedFile
+		ok    bool
+	}{
+		{&client{}, nil, true},
+		{&client{prepareErr: boom}, nil, false},
+		{&client{}, files, false}, // no staging endpoint
+		{&client{runErr: boom}, nil, false},
+	}
+
+	for i, test := range tests {
+		cc := &closer{}
+		j, err := execute(context.Background(), test.c, cc, &pipepb.Pipeline{}, &Options{Artifacts: test.files})
+		if (err == nil) != test.ok {
+			t.Errorf("execute(%v) = %v, want ok=%v", i, err, test.ok)
+			continue
+		}
+		if !test.ok {
+			if !cc.closed {
+				t.Errorf("execute(%v) failed without closing the connection", i)
+			}
+			continue
+		}
+
+		if j.ID != "job" || cc.closed {
+			t.Errorf("execute(%v) = %v, closed=%v, want open job", i, j.ID, cc.closed)
+		}
+		j.Close()
+		if !cc.closed {
+			t.Errorf("execute(%v): Job.Close did not close the connection", i)
+		}
+	}
+}